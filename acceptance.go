@@ -0,0 +1,68 @@
+package tftest
+
+import (
+	"os"
+	"sync"
+)
+
+// accEnvVar is the environment variable that gates acceptance tests, named
+// after the convention established across the Terraform provider ecosystem.
+const accEnvVar = "TF_ACC"
+
+// AcceptanceTest skips the running test, logging why, unless TF_ACC=1 is
+// set in the environment. Call this at the top of any test that has side
+// effects outside of the test process, such as making real API calls.
+func AcceptanceTest(t TestControl) {
+	t.Helper()
+	if os.Getenv(accEnvVar) != "1" {
+		t.Skip(accEnvVar + "=1 must be set to run acceptance tests")
+	}
+}
+
+// RequireAcceptance is a Helper method equivalent of AcceptanceTest, kept
+// alongside RequireNewWorkingDir for callers that prefer to reach every
+// precondition through the Helper they already have in hand.
+func (h *Helper) RequireAcceptance(t TestControl) {
+	t.Helper()
+	AcceptanceTest(t)
+}
+
+// guardsMu guards access to guards, since tests across a package may call
+// RegisterGuard and Helper.Guard concurrently (e.g. from parallel
+// subtests).
+var guardsMu sync.RWMutex
+
+// guards holds every guard function registered with RegisterGuard, keyed by
+// name.
+var guards = map[string]func(TestControl){}
+
+// RegisterGuard registers a named guard function for later use with
+// Helper.Guard. Guards encode preconditions that a test requires in order
+// to run meaningfully, such as "requires AWS credentials" or "requires
+// network access", so that callers across a provider's test suite can
+// declare them by name instead of reimplementing the same checks
+// everywhere.
+//
+// RegisterGuard is typically called from an init function.
+func RegisterGuard(name string, fn func(TestControl)) {
+	guardsMu.Lock()
+	defer guardsMu.Unlock()
+	guards[name] = fn
+}
+
+// Guard runs each named guard in turn against t, skipping or failing the
+// running test if any guard's precondition is not met. It panics if any
+// name has not been registered with RegisterGuard, since that indicates a
+// mistake in the calling test rather than an unmet precondition.
+func (h *Helper) Guard(t TestControl, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		guardsMu.RLock()
+		fn, ok := guards[name]
+		guardsMu.RUnlock()
+		if !ok {
+			panic("tftest: no guard registered with name " + name)
+		}
+		fn(t)
+	}
+}
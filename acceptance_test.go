@@ -0,0 +1,101 @@
+package tftest
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+)
+
+// fakeT is a minimal TestControl implementation for exercising code paths
+// that call Fatal or Skip, without tearing down the real *testing.T running
+// the test.
+type fakeT struct {
+	failed  bool
+	skipped bool
+	msgs    []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Skip(args ...interface{}) {
+	f.skipped = true
+	f.msgs = append(f.msgs, fmt.Sprint(args...))
+}
+
+func (f *fakeT) SkipNow() {
+	f.skipped = true
+}
+
+func (f *fakeT) Fatal(args ...interface{}) {
+	f.failed = true
+	f.msgs = append(f.msgs, fmt.Sprint(args...))
+}
+
+func TestAcceptanceTest(t *testing.T) {
+	t.Run("TF_ACC not set", func(t *testing.T) {
+		defer os.Unsetenv(accEnvVar)
+		os.Unsetenv(accEnvVar)
+
+		ft := &fakeT{}
+		AcceptanceTest(ft)
+		if !ft.skipped {
+			t.Error("expected the test to be skipped")
+		}
+	})
+
+	t.Run("TF_ACC=1", func(t *testing.T) {
+		os.Setenv(accEnvVar, "1")
+		defer os.Unsetenv(accEnvVar)
+
+		ft := &fakeT{}
+		AcceptanceTest(ft)
+		if ft.skipped {
+			t.Error("did not expect the test to be skipped")
+		}
+	})
+}
+
+func TestGuardUnknownName(t *testing.T) {
+	h := &Helper{}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Guard to panic for an unregistered guard name")
+		}
+	}()
+	h.Guard(&fakeT{}, "does-not-exist")
+}
+
+func TestGuardRunsRegisteredGuards(t *testing.T) {
+	called := false
+	RegisterGuard("tftest-test-guard", func(t TestControl) {
+		called = true
+	})
+
+	h := &Helper{}
+	h.Guard(&fakeT{}, "tftest-test-guard")
+
+	if !called {
+		t.Error("expected the registered guard function to be called")
+	}
+}
+
+func TestGuardConcurrentAccess(t *testing.T) {
+	h := &Helper{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			RegisterGuard(fmt.Sprintf("tftest-concurrent-guard-%d", i), func(t TestControl) {})
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			RegisterGuard(fmt.Sprintf("tftest-concurrent-guard-%d", i), func(t TestControl) {})
+			h.Guard(&fakeT{}, fmt.Sprintf("tftest-concurrent-guard-%d", i))
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,62 @@
+package tftest
+
+import (
+	"fmt"
+	"io/ioutil"
+)
+
+// CLIConfigOptions configures the CLI configuration file generated by
+// Helper.WriteCLIConfig.
+type CLIConfigOptions struct {
+	// PluginVersion selects which registered plugin version (see
+	// Helper.PluginVersions) Terraform should be steered towards via
+	// dev_overrides. Defaults to "current".
+	PluginVersion string
+}
+
+// WriteCLIConfig generates a Terraform CLI configuration file under the
+// helper's base directory containing a provider_installation block that
+// dev_overrides the configured plugin's namespace (Config.PluginNamespace)
+// to its installed plugin directory, and returns the path to that file.
+// Every WorkingDir created afterwards has TF_CLI_CONFIG_FILE set to this
+// path automatically.
+//
+// This covers the provider installation method used by newer Terraform
+// versions, which resolve providers via CLI-config-declared
+// provider_installation blocks (dev_overrides, filesystem_mirror,
+// network_mirror) rather than the deprecated -plugin-dir flag.
+func (h *Helper) WriteCLIConfig(opts CLIConfigOptions) (string, error) {
+	if h.pluginNamespace == "" {
+		return "", fmt.Errorf("no PluginNamespace configured; dev_overrides requires one")
+	}
+
+	label := opts.PluginVersion
+	if label == "" {
+		label = currentPluginVersion
+	}
+	pluginDir, ok := h.PluginDirForVersion(label)
+	if !ok {
+		return "", fmt.Errorf("no plugin version registered under label %q", label)
+	}
+
+	content := fmt.Sprintf(`provider_installation {
+  dev_overrides {
+    %q = %q
+  }
+  direct {}
+}
+`, h.pluginNamespace, pluginDir)
+
+	f, err := ioutil.TempFile(h.baseDir, "dev-*.tfrc")
+	if err != nil {
+		return "", fmt.Errorf("failed to create CLI config file: %s", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(content); err != nil {
+		return "", fmt.Errorf("failed to write CLI config file at %s: %s", f.Name(), err)
+	}
+
+	h.setCLIConfigPath(f.Name())
+	return f.Name(), nil
+}
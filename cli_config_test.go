@@ -0,0 +1,72 @@
+package tftest
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestWriteCLIConfig(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "tftest-cliconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+
+	h := &Helper{
+		baseDir:         baseDir,
+		pluginNamespace: "registry.terraform.io/hashicorp/foo",
+		pluginDirs:      map[string]string{currentPluginVersion: "/plugins/current"},
+	}
+
+	path, err := h.WriteCLIConfig(CLIConfigOptions{})
+	if err != nil {
+		t.Fatalf("WriteCLIConfig returned an error: %s", err)
+	}
+
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `provider_installation {
+  dev_overrides {
+    "registry.terraform.io/hashicorp/foo" = "/plugins/current"
+  }
+  direct {}
+}
+`
+	if string(got) != want {
+		t.Errorf("CLI config content =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteCLIConfigRequiresNamespace(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "tftest-cliconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+
+	h := &Helper{baseDir: baseDir, pluginDirs: map[string]string{currentPluginVersion: "/plugins/current"}}
+	if _, err := h.WriteCLIConfig(CLIConfigOptions{}); err == nil {
+		t.Fatal("expected an error when PluginNamespace is not configured")
+	}
+}
+
+func TestWriteCLIConfigUnknownVersion(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "tftest-cliconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+
+	h := &Helper{
+		baseDir:         baseDir,
+		pluginNamespace: "registry.terraform.io/hashicorp/foo",
+		pluginDirs:      map[string]string{currentPluginVersion: "/plugins/current"},
+	}
+	if _, err := h.WriteCLIConfig(CLIConfigOptions{PluginVersion: "9.9.9"}); err == nil {
+		t.Fatal("expected an error for an unregistered plugin version")
+	}
+}
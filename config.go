@@ -0,0 +1,94 @@
+package tftest
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Config is the configuration for a Helper, specifying the locations of the
+// Terraform CLI executable and the plugin executable(s) under test.
+//
+// Most callers should construct a Config via DiscoverConfig rather than
+// building one directly.
+type Config struct {
+	// TerraformExec is the path to the Terraform CLI executable to use
+	// when running tests.
+	TerraformExec string
+
+	// PluginName is the full name of the plugin executable, including the
+	// "terraform-provider-" prefix.
+	PluginName string
+
+	// CurrentPluginExec is the path to the plugin executable under test.
+	CurrentPluginExec string
+
+	// PreviousPluginExec is the path to a previous release of the plugin
+	// executable, for use in upgrade tests. This may be left unset if no
+	// previous version is available.
+	//
+	// This is a convenience equivalent to setting PluginExecs["previous"].
+	PreviousPluginExec string
+
+	// PluginExecs registers arbitrary plugin versions for use in matrix or
+	// upgrade tests, keyed by a caller-chosen label (conventionally a real
+	// version number, e.g. "1.0.0"). CurrentPluginExec and
+	// PreviousPluginExec, if set, are merged into this map under the
+	// "current" and "previous" labels respectively.
+	PluginExecs map[string]string
+
+	// PluginNamespace is the provider source namespace to emulate when
+	// laying out plugin directories, e.g. "registry.terraform.io/hashicorp/foo".
+	// When set, InitHelper builds a full <namespace>/<version>/<os>_<arch>
+	// tree alongside the legacy flat layout, so that tests can exercise
+	// Terraform versions that resolve plugins via the registry addressing
+	// scheme rather than the legacy -plugin-dir flat layout. The plugin
+	// registered under the "previous" label (PreviousPluginExec) gets only
+	// the legacy flat layout.
+	PluginNamespace string
+
+	// PluginVersion is the version number to emulate in the OS_ARCH plugin
+	// directory layout for the "current" plugin exec. It is ignored unless
+	// PluginNamespace is also set, and defaults to "0.0.1" if left unset.
+	PluginVersion string
+
+	// SkipPluginCache disables the shared TF_PLUGIN_CACHE_DIR that Helper
+	// otherwise sets up automatically. Set this for tests that need to
+	// exercise Terraform's fresh-init behavior, since a populated plugin
+	// cache changes what "terraform init" does.
+	SkipPluginCache bool
+}
+
+const (
+	terraformExecEnvVar   = "TF_ACC_TERRAFORM_PATH"
+	pluginExecEnvVar      = "TF_ACC_PLUGIN_PATH"
+	prevPluginExecEnvVar  = "TF_ACC_PREVIOUS_PLUGIN_PATH"
+	skipPluginCacheEnvVar = "TF_ACC_DISABLE_PLUGIN_CACHE"
+)
+
+// DiscoverConfig discovers a testing configuration by reading environment
+// variables and, failing that, consulting the PATH. This is the standard
+// way to construct a Config, used by AutoInitHelper.
+func DiscoverConfig(pluginName string) (*Config, error) {
+	tfExec := os.Getenv(terraformExecEnvVar)
+	if tfExec == "" {
+		var err error
+		tfExec, err = exec.LookPath("terraform")
+		if err != nil {
+			return nil, fmt.Errorf("cannot find terraform CLI executable: %s", err)
+		}
+	}
+
+	currentPluginExec := os.Getenv(pluginExecEnvVar)
+	if currentPluginExec == "" {
+		return nil, fmt.Errorf("%s must be set to the path of the plugin executable under test", pluginExecEnvVar)
+	}
+
+	return &Config{
+		TerraformExec:      tfExec,
+		PluginName:         pluginName,
+		CurrentPluginExec:  currentPluginExec,
+		PreviousPluginExec: os.Getenv(prevPluginExecEnvVar),
+		SkipPluginCache:    os.Getenv(skipPluginCacheEnvVar) != "",
+	}, nil
+}
@@ -5,6 +5,10 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/kmoe/terraform-plugin-test/workdir"
 )
 
 const subprocessCurrentSigil = "4acd63807899403ca4859f5bb948d2c6"
@@ -28,13 +32,29 @@ func AutoInitProviderHelper(name string) *Helper {
 	return helper
 }
 
+// currentPluginVersion and previousPluginVersion are the well-known version
+// labels used by the back-compat PluginDir/PreviousPluginDir methods.
+const (
+	currentPluginVersion  = "current"
+	previousPluginVersion = "previous"
+)
+
 // Helper is intended as a per-package singleton created in TestMain which
 // other tests in a package can use to create Terraform execution contexts
 type Helper struct {
-	baseDir                      string
-	pluginName                   string
-	terraformExec                string
-	thisPluginDir, prevPluginDir string
+	baseDir         string
+	pluginName      string
+	pluginNamespace string
+	terraformExec   string
+	pluginDirs      map[string]string
+	pluginCacheDir  string
+
+	// cliConfigMu guards cliConfigPath, which is the only Helper field
+	// mutated after InitHelper returns. Helper is a shared per-package
+	// singleton that tests may use concurrently (e.g. via t.Parallel()),
+	// so WriteCLIConfig and NewWorkingDir must synchronize on it.
+	cliConfigMu   sync.RWMutex
+	cliConfigPath string
 }
 
 // AutoInitHelper uses the auto-discovery behavior of DiscoverConfig to prepare
@@ -66,38 +86,66 @@ func InitHelper(config *Config) (*Helper, error) {
 		return nil, fmt.Errorf("failed to create temporary directory for test helper: %s", err)
 	}
 
-	var thisPluginDir, prevPluginDir string
+	pluginExecs := map[string]string{}
+	for label, execPath := range config.PluginExecs {
+		pluginExecs[label] = execPath
+	}
 	if config.CurrentPluginExec != "" {
-		thisPluginDir, err = ioutil.TempDir(baseDir, "plugins-current")
+		pluginExecs[currentPluginVersion] = config.CurrentPluginExec
+	}
+	if config.PreviousPluginExec != "" {
+		pluginExecs[previousPluginVersion] = config.PreviousPluginExec
+	}
+	if len(pluginExecs) == 0 {
+		return nil, fmt.Errorf("no plugin executables configured: set CurrentPluginExec or PluginExecs")
+	}
+
+	pluginDirs := make(map[string]string, len(pluginExecs))
+	for label, execPath := range pluginExecs {
+		pluginDir, err := ioutil.TempDir(baseDir, "plugins-"+label)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create temporary directory for -plugin-dir: %s", err)
 		}
-		currentExecPath := filepath.Join(thisPluginDir, config.PluginName)
-		err = os.Symlink(config.CurrentPluginExec, currentExecPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create symlink at %s to %s: %s", currentExecPath, config.CurrentPluginExec, err)
+		linkPath := filepath.Join(pluginDir, config.PluginName)
+		if err := os.Symlink(execPath, linkPath); err != nil {
+			return nil, fmt.Errorf("failed to create symlink at %s to %s: %s", linkPath, execPath, err)
 		}
-	} else {
-		return nil, fmt.Errorf("CurrentPluginExec is not set")
-	}
-	if config.PreviousPluginExec != "" {
-		prevPluginDir, err = ioutil.TempDir(baseDir, "plugins-previous")
-		if err != nil {
-			return nil, fmt.Errorf("failed to create temporary directory for previous -plugin-dir: %s", err)
+
+		// previousPluginVersion is a legacy label carried over from
+		// Config.PreviousPluginExec, not a real version number, so there is
+		// no sensible value to put in the registry-style OS_ARCH layout for
+		// it; skip that layout and rely on the legacy flat one instead.
+		if config.PluginNamespace != "" && label != previousPluginVersion {
+			version := config.PluginVersion
+			if label != currentPluginVersion {
+				version = label
+			}
+			if version == "" {
+				version = "0.0.1"
+			}
+			if err := symlinkPluginOSArch(pluginDir, config.PluginNamespace, version, config.PluginName, execPath); err != nil {
+				return nil, err
+			}
 		}
-		prevExecPath := filepath.Join(prevPluginDir, config.PluginName)
-		err = os.Symlink(config.PreviousPluginExec, prevExecPath)
+
+		pluginDirs[label] = pluginDir
+	}
+
+	var pluginCacheDir string
+	if !config.SkipPluginCache {
+		pluginCacheDir, err = ioutil.TempDir(baseDir, "plugin-cache")
 		if err != nil {
-			return nil, fmt.Errorf("failed to create symlink at %s to %s: %s", prevExecPath, config.PreviousPluginExec, err)
+			return nil, fmt.Errorf("failed to create temporary directory for plugin cache: %s", err)
 		}
 	}
 
 	return &Helper{
-		baseDir:       baseDir,
-		pluginName:    config.PluginName,
-		terraformExec: config.TerraformExec,
-		thisPluginDir: thisPluginDir,
-		prevPluginDir: prevPluginDir,
+		baseDir:         baseDir,
+		pluginName:      config.PluginName,
+		pluginNamespace: config.PluginNamespace,
+		terraformExec:   config.TerraformExec,
+		pluginDirs:      pluginDirs,
+		pluginCacheDir:  pluginCacheDir,
 	}, nil
 }
 
@@ -117,14 +165,29 @@ func (h *Helper) Close() error {
 // program exits, the Close method on the helper itself will attempt to
 // delete it.
 func (h *Helper) NewWorkingDir() (*WorkingDir, error) {
-	dir, err := ioutil.TempDir(h.baseDir, "work")
+	rootDir, err := ioutil.TempDir(h.baseDir, "work")
 	if err != nil {
 		return nil, err
 	}
 
+	cliConfigPath := h.cliConfigPathValue()
+
+	dir := workdir.NewDir(rootDir)
+	// A generated CLI config takes over plugin resolution via
+	// dev_overrides, which Terraform requires to be the only installation
+	// method in play; forcing -plugin-dir at the same time would make
+	// Terraform ignore the CLI config entirely.
+	if cliConfigPath == "" {
+		if pluginDir := h.PluginDir(); pluginDir != "" {
+			dir.SetForcedPluginDirs([]string{pluginDir})
+		}
+	}
+	dir.SetPluginCacheDir(h.PluginCacheDir())
+	dir.SetCLIConfigPath(cliConfigPath)
+
 	return &WorkingDir{
-		h:       h,
-		baseDir: dir,
+		h:   h,
+		dir: dir,
 	}, nil
 }
 
@@ -146,7 +209,8 @@ func (h *Helper) RequireNewWorkingDir(t TestControl) *WorkingDir {
 // HasPreviousVersion returns true if and only if the receiving helper has a
 // previous plugin version available for use in tests.
 func (h *Helper) HasPreviousVersion() bool {
-	return h.prevPluginDir != ""
+	_, ok := h.pluginDirs[previousPluginVersion]
+	return ok
 }
 
 // TerraformExecPath returns the location of the Terraform CLI executable that
@@ -155,11 +219,47 @@ func (h *Helper) TerraformExecPath() string {
 	return h.terraformExec
 }
 
+// PluginDirForVersion returns the directory that should be used as the
+// -plugin-dir when running "terraform init" in order to make Terraform
+// detect the plugin version registered under the given label, and whether
+// that label is registered at all.
+//
+// Labels are whatever Config.PluginExecs (or the legacy
+// CurrentPluginExec/PreviousPluginExec fields) used as keys, so callers are
+// free to use real version numbers (e.g. "1.0.0") to build a matrix across
+// historical releases.
+func (h *Helper) PluginDirForVersion(label string) (string, bool) {
+	dir, ok := h.pluginDirs[label]
+	return dir, ok
+}
+
+// PluginVersions returns the labels of all plugin versions registered with
+// the receiving helper, in no particular order.
+func (h *Helper) PluginVersions() []string {
+	labels := make([]string, 0, len(h.pluginDirs))
+	for label := range h.pluginDirs {
+		labels = append(labels, label)
+	}
+	return labels
+}
+
 // PluginDir returns the directory that should be used as the -plugin-dir when
 // running "terraform init" in order to make Terraform detect the current
 // version of the plugin.
 func (h *Helper) PluginDir() string {
-	return h.thisPluginDir
+	dir, _ := h.PluginDirForVersion(currentPluginVersion)
+	return dir
+}
+
+// PluginCacheDir returns the directory that should be used as the
+// TF_PLUGIN_CACHE_DIR when running "terraform init", so that repeated inits
+// across many working directories can share already-installed plugins
+// instead of re-downloading or re-copying them.
+//
+// This returns an empty string if the plugin cache has been disabled via
+// Config.SkipPluginCache.
+func (h *Helper) PluginCacheDir() string {
+	return h.pluginCacheDir
 }
 
 // PreviousPluginDir returns the directory that should be used as the -plugin-dir
@@ -170,8 +270,54 @@ func (h *Helper) PluginDir() string {
 // RequirePreviousVersion or HasPreviousVersion to ensure a previous version is
 // available before calling this.
 func (h *Helper) PreviousPluginDir() string {
-	if h.prevPluginDir != "" {
+	dir, _ := h.PluginDirForVersion(previousPluginVersion)
+	if dir == "" {
 		panic("PreviousPluginDir not available")
 	}
-	return h.prevPluginDir
+	return dir
+}
+
+// RequirePreviousVersion fails the running test immediately unless the
+// receiving helper has a previous plugin version available, so that callers
+// can guard PreviousPluginDir the same way RequireNewWorkingDir guards
+// NewWorkingDir.
+func (h *Helper) RequirePreviousVersion(t TestControl) {
+	t.Helper()
+
+	if !h.HasPreviousVersion() {
+		t := testingT{t}
+		t.Fatalf("no previous plugin version is configured")
+	}
+}
+
+// cliConfigPathValue returns the path of the CLI config file generated by
+// WriteCLIConfig, if any, synchronizing with concurrent calls to it.
+func (h *Helper) cliConfigPathValue() string {
+	h.cliConfigMu.RLock()
+	defer h.cliConfigMu.RUnlock()
+	return h.cliConfigPath
+}
+
+// setCLIConfigPath records the path of a newly generated CLI config file,
+// synchronizing with concurrent calls to NewWorkingDir.
+func (h *Helper) setCLIConfigPath(path string) {
+	h.cliConfigMu.Lock()
+	defer h.cliConfigMu.Unlock()
+	h.cliConfigPath = path
+}
+
+// symlinkPluginOSArch symlinks execPath into pluginDir using the
+// <namespace>/<version>/<os>_<arch>/<binary> layout that Terraform 0.13+
+// expects to find under a -plugin-dir, alongside the legacy flat layout
+// that InitHelper always creates.
+func symlinkPluginOSArch(pluginDir, namespace, version, pluginName, execPath string) error {
+	osArchDir := filepath.Join(pluginDir, namespace, version, runtime.GOOS+"_"+runtime.GOARCH)
+	if err := os.MkdirAll(osArchDir, 0755); err != nil {
+		return fmt.Errorf("failed to create plugin directory %s: %s", osArchDir, err)
+	}
+	linkPath := filepath.Join(osArchDir, fmt.Sprintf("%s_v%s", pluginName, version))
+	if err := os.Symlink(execPath, linkPath); err != nil {
+		return fmt.Errorf("failed to create symlink at %s to %s: %s", linkPath, execPath, err)
+	}
+	return nil
 }
@@ -0,0 +1,171 @@
+package tftest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSymlinkPluginOSArch(t *testing.T) {
+	srcDir, err := ioutil.TempDir("", "tftest-symlink-src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(srcDir)
+
+	execPath := filepath.Join(srcDir, "terraform-provider-foo")
+	if err := ioutil.WriteFile(execPath, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	pluginDir, err := ioutil.TempDir("", "tftest-symlink-plugindir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(pluginDir)
+
+	if err := symlinkPluginOSArch(pluginDir, "registry.terraform.io/hashicorp/foo", "1.2.3", "terraform-provider-foo", execPath); err != nil {
+		t.Fatalf("symlinkPluginOSArch returned an error: %s", err)
+	}
+
+	wantLink := filepath.Join(
+		pluginDir,
+		"registry.terraform.io/hashicorp/foo",
+		"1.2.3",
+		runtime.GOOS+"_"+runtime.GOARCH,
+		"terraform-provider-foo_v1.2.3",
+	)
+	target, err := os.Readlink(wantLink)
+	if err != nil {
+		t.Fatalf("expected a symlink at %s: %s", wantLink, err)
+	}
+	if target != execPath {
+		t.Errorf("symlink target = %s, want %s", target, execPath)
+	}
+}
+
+func TestInitHelperPluginCacheDirShared(t *testing.T) {
+	config := &Config{
+		PluginName:        "terraform-provider-test",
+		TerraformExec:     "/usr/bin/true",
+		CurrentPluginExec: "/nonexistent/terraform-provider-test",
+	}
+
+	h, err := InitHelper(config)
+	if err != nil {
+		t.Fatalf("InitHelper returned an error: %s", err)
+	}
+	defer h.Close()
+
+	if h.PluginCacheDir() == "" {
+		t.Fatal("expected PluginCacheDir to be set by default")
+	}
+	if _, err := os.Stat(h.PluginCacheDir()); err != nil {
+		t.Errorf("PluginCacheDir() does not exist on disk: %s", err)
+	}
+
+	wd1, err := h.NewWorkingDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wd1.Close()
+
+	wd2, err := h.NewWorkingDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wd2.Close()
+
+	if got := wd1.dir.PluginCacheDir(); got != h.PluginCacheDir() {
+		t.Errorf("wd1's plugin cache dir = %q, want the helper's %q", got, h.PluginCacheDir())
+	}
+	if got := wd2.dir.PluginCacheDir(); got != h.PluginCacheDir() {
+		t.Errorf("wd2's plugin cache dir = %q, want the helper's %q", got, h.PluginCacheDir())
+	}
+}
+
+func TestInitHelperNamespaceWithLegacyPreviousExec(t *testing.T) {
+	srcDir, err := ioutil.TempDir("", "tftest-legacy-previous-src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(srcDir)
+
+	currentExec := filepath.Join(srcDir, "terraform-provider-test-current")
+	previousExec := filepath.Join(srcDir, "terraform-provider-test-previous")
+	for _, p := range []string{currentExec, previousExec} {
+		if err := ioutil.WriteFile(p, []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &Config{
+		PluginName:         "terraform-provider-test",
+		TerraformExec:      "/usr/bin/true",
+		CurrentPluginExec:  currentExec,
+		PreviousPluginExec: previousExec,
+		PluginNamespace:    "registry.terraform.io/hashicorp/test",
+		PluginVersion:      "1.2.3",
+	}
+
+	h, err := InitHelper(config)
+	if err != nil {
+		t.Fatalf("InitHelper returned an error: %s", err)
+	}
+	defer h.Close()
+
+	currentDir, ok := h.PluginDirForVersion(currentPluginVersion)
+	if !ok {
+		t.Fatal("expected a plugin dir registered for \"current\"")
+	}
+	wantCurrentLink := filepath.Join(
+		currentDir,
+		"registry.terraform.io/hashicorp/test",
+		"1.2.3",
+		runtime.GOOS+"_"+runtime.GOARCH,
+		"terraform-provider-test_v1.2.3",
+	)
+	if _, err := os.Lstat(wantCurrentLink); err != nil {
+		t.Errorf("expected an OS_ARCH symlink for \"current\" at %s: %s", wantCurrentLink, err)
+	}
+
+	previousDir, ok := h.PluginDirForVersion(previousPluginVersion)
+	if !ok {
+		t.Fatal("expected a plugin dir registered for \"previous\"")
+	}
+	previousNamespaceDir := filepath.Join(previousDir, "registry.terraform.io/hashicorp/test")
+	if _, err := os.Stat(previousNamespaceDir); !os.IsNotExist(err) {
+		t.Errorf("expected no OS_ARCH layout under the legacy \"previous\" plugin dir, but found %s", previousNamespaceDir)
+	}
+}
+
+func TestInitHelperSkipPluginCache(t *testing.T) {
+	config := &Config{
+		PluginName:        "terraform-provider-test",
+		TerraformExec:     "/usr/bin/true",
+		CurrentPluginExec: "/nonexistent/terraform-provider-test",
+		SkipPluginCache:   true,
+	}
+
+	h, err := InitHelper(config)
+	if err != nil {
+		t.Fatalf("InitHelper returned an error: %s", err)
+	}
+	defer h.Close()
+
+	if got := h.PluginCacheDir(); got != "" {
+		t.Errorf("PluginCacheDir() = %q, want \"\" when SkipPluginCache is set", got)
+	}
+
+	wd, err := h.NewWorkingDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wd.Close()
+
+	if got := wd.dir.PluginCacheDir(); got != "" {
+		t.Errorf("WorkingDir's plugin cache dir = %q, want \"\"", got)
+	}
+}
@@ -0,0 +1,24 @@
+package tftest
+
+import "fmt"
+
+// TestControl is an interface requiring a subset of *testing.T, allowing
+// this package to accept test objects without creating a hard dependency
+// on the testing package.
+type TestControl interface {
+	Helper()
+	Skip(args ...interface{})
+	SkipNow()
+	Fatal(args ...interface{})
+}
+
+// testingT adapts a TestControl to provide a conventional Fatalf method,
+// since TestControl itself exposes only the variadic Fatal.
+type testingT struct {
+	TestControl
+}
+
+func (t testingT) Fatalf(format string, args ...interface{}) {
+	t.Helper()
+	t.Fatal(fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,60 @@
+package tftest
+
+// UpgradeStep is a single step to run against an initialized WorkingDir as
+// part of an UpgradeTest, typically asserting something about the plan or
+// state produced by the "from" or "to" version of the plugin.
+type UpgradeStep func(t TestControl, wd *WorkingDir)
+
+// UpgradeTest drives a single WorkingDir through init and apply using the
+// plugin version registered under the "from" label, runs steps against the
+// result, then re-inits the same working directory using the version
+// registered under the "to" label, applies again, and runs steps a second
+// time.
+//
+// This is the first-class entry point for the upgrade-test use case this
+// package exists to enable: asserting that Terraform can carry a plan or
+// state produced by one plugin version forward through a later one.
+func (h *Helper) UpgradeTest(t TestControl, from, to string, steps ...UpgradeStep) {
+	t.Helper()
+	tt := testingT{t}
+
+	fromDir, ok := h.PluginDirForVersion(from)
+	if !ok {
+		tt.Fatalf("no plugin version registered under label %q", from)
+		return
+	}
+	toDir, ok := h.PluginDirForVersion(to)
+	if !ok {
+		tt.Fatalf("no plugin version registered under label %q", to)
+		return
+	}
+
+	wd := h.RequireNewWorkingDir(t)
+	defer wd.Close()
+
+	wd.SetForcedPluginDirs([]string{fromDir})
+	if err := wd.Init(); err != nil {
+		tt.Fatalf("failed to init with %q plugin: %s", from, err)
+		return
+	}
+	if err := wd.Apply(); err != nil {
+		tt.Fatalf("failed to apply with %q plugin: %s", from, err)
+		return
+	}
+	for _, step := range steps {
+		step(t, wd)
+	}
+
+	wd.SetForcedPluginDirs([]string{toDir})
+	if err := wd.Init(); err != nil {
+		tt.Fatalf("failed to re-init with %q plugin: %s", to, err)
+		return
+	}
+	if err := wd.Apply(); err != nil {
+		tt.Fatalf("failed to apply with %q plugin after upgrade: %s", to, err)
+		return
+	}
+	for _, step := range steps {
+		step(t, wd)
+	}
+}
@@ -0,0 +1,84 @@
+package tftest
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestPluginDirForVersion(t *testing.T) {
+	h := &Helper{pluginDirs: map[string]string{
+		currentPluginVersion: "/plugins/current",
+		"1.0.0":              "/plugins/1.0.0",
+	}}
+
+	if dir, ok := h.PluginDirForVersion(currentPluginVersion); !ok || dir != "/plugins/current" {
+		t.Errorf("PluginDirForVersion(%q) = (%q, %v), want (\"/plugins/current\", true)", currentPluginVersion, dir, ok)
+	}
+	if dir, ok := h.PluginDirForVersion("1.0.0"); !ok || dir != "/plugins/1.0.0" {
+		t.Errorf("PluginDirForVersion(\"1.0.0\") = (%q, %v), want (\"/plugins/1.0.0\", true)", dir, ok)
+	}
+	if dir, ok := h.PluginDirForVersion("2.0.0"); ok {
+		t.Errorf("PluginDirForVersion(\"2.0.0\") = (%q, %v), want ok=false", dir, ok)
+	}
+}
+
+func TestPluginVersions(t *testing.T) {
+	h := &Helper{pluginDirs: map[string]string{
+		"1.0.0": "/plugins/1.0.0",
+		"1.2.3": "/plugins/1.2.3",
+	}}
+
+	got := h.PluginVersions()
+	sort.Strings(got)
+	want := []string{"1.0.0", "1.2.3"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("PluginVersions() = %v, want %v", got, want)
+	}
+}
+
+func TestHasPreviousVersion(t *testing.T) {
+	withPrev := &Helper{pluginDirs: map[string]string{previousPluginVersion: "/plugins/previous"}}
+	if !withPrev.HasPreviousVersion() {
+		t.Error("expected HasPreviousVersion to be true when \"previous\" is registered")
+	}
+
+	withoutPrev := &Helper{pluginDirs: map[string]string{currentPluginVersion: "/plugins/current"}}
+	if withoutPrev.HasPreviousVersion() {
+		t.Error("expected HasPreviousVersion to be false when \"previous\" is not registered")
+	}
+}
+
+func TestPreviousPluginDirPanicsWhenUnavailable(t *testing.T) {
+	h := &Helper{pluginDirs: map[string]string{currentPluginVersion: "/plugins/current"}}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected PreviousPluginDir to panic when no previous version is configured")
+		}
+	}()
+	h.PreviousPluginDir()
+}
+
+func TestPreviousPluginDirReturnsDirWhenAvailable(t *testing.T) {
+	h := &Helper{pluginDirs: map[string]string{previousPluginVersion: "/plugins/previous"}}
+
+	if got := h.PreviousPluginDir(); got != "/plugins/previous" {
+		t.Errorf("PreviousPluginDir() = %q, want \"/plugins/previous\"", got)
+	}
+}
+
+func TestRequirePreviousVersion(t *testing.T) {
+	withPrev := &Helper{pluginDirs: map[string]string{previousPluginVersion: "/plugins/previous"}}
+	ft := &fakeT{}
+	withPrev.RequirePreviousVersion(ft)
+	if ft.failed {
+		t.Error("did not expect RequirePreviousVersion to fail when a previous version is configured")
+	}
+
+	withoutPrev := &Helper{pluginDirs: map[string]string{currentPluginVersion: "/plugins/current"}}
+	ft = &fakeT{}
+	withoutPrev.RequirePreviousVersion(ft)
+	if !ft.failed {
+		t.Error("expected RequirePreviousVersion to fail when no previous version is configured")
+	}
+}
@@ -0,0 +1,107 @@
+// Package workdir models the on-disk layout of a single Terraform working
+// directory: its root path, its data directory, and the various overrides
+// that steer where Terraform looks for plugins.
+//
+// It is deliberately modeled after Terraform core's own internal
+// command/workdir package, so that anyone familiar with that package's
+// vocabulary (data dir, forced plugin dirs, plugin cache dir) will
+// recognize the concepts here.
+package workdir
+
+import "path/filepath"
+
+// DefaultDataDirName is the name of the subdirectory, relative to a
+// working directory's root, that Terraform uses by default to store its
+// internal state (equivalent to the TF_DATA_DIR override).
+const DefaultDataDirName = ".terraform"
+
+// Dir represents the on-disk layout of a single Terraform working
+// directory, and owns all of the decisions about where its various
+// supporting files live.
+type Dir struct {
+	rootDir string
+	dataDir string
+
+	pluginCacheDir   string
+	forcedPluginDirs []string
+	cliConfigPath    string
+}
+
+// NewDir creates a new Dir rooted at the given path, which must already
+// exist. The data directory defaults to DefaultDataDirName.
+func NewDir(rootDir string) *Dir {
+	return &Dir{
+		rootDir: rootDir,
+		dataDir: DefaultDataDirName,
+	}
+}
+
+// RootDir returns the root path of the working directory.
+func (d *Dir) RootDir() string {
+	return d.rootDir
+}
+
+// DataDir returns the absolute path of the working directory's data
+// directory, where Terraform keeps its internal state.
+func (d *Dir) DataDir() string {
+	return filepath.Join(d.rootDir, d.dataDir)
+}
+
+// SetDataDir overrides the name of the data directory, relative to the
+// working directory's root. This corresponds to Terraform's TF_DATA_DIR
+// environment variable.
+func (d *Dir) SetDataDir(name string) {
+	d.dataDir = name
+}
+
+// PluginCacheDir returns the directory that should be used as
+// TF_PLUGIN_CACHE_DIR, or an empty string if no plugin cache is configured.
+func (d *Dir) PluginCacheDir() string {
+	return d.pluginCacheDir
+}
+
+// SetPluginCacheDir sets the directory that should be used as
+// TF_PLUGIN_CACHE_DIR.
+func (d *Dir) SetPluginCacheDir(path string) {
+	d.pluginCacheDir = path
+}
+
+// ForcedPluginDirs returns the set of directories that should be passed to
+// "terraform init" as -plugin-dir arguments, forcing Terraform to look only
+// in those directories for plugins.
+func (d *Dir) ForcedPluginDirs() []string {
+	return d.forcedPluginDirs
+}
+
+// SetForcedPluginDirs sets the directories that should be passed to
+// "terraform init" as -plugin-dir arguments.
+func (d *Dir) SetForcedPluginDirs(dirs []string) {
+	d.forcedPluginDirs = dirs
+}
+
+// CLIConfigPath returns the path of a CLI configuration file that should be
+// used for operations in this working directory (via TF_CLI_CONFIG_FILE),
+// or an empty string if none has been generated.
+func (d *Dir) CLIConfigPath() string {
+	return d.cliConfigPath
+}
+
+// SetCLIConfigPath sets the path of a generated CLI configuration file that
+// should be used for operations in this working directory.
+func (d *Dir) SetCLIConfigPath(path string) {
+	d.cliConfigPath = path
+}
+
+// NewChild returns a new Dir rooted at the given subpath of the receiver,
+// inheriting the receiver's data dir name, plugin cache dir, forced plugin
+// dirs, and CLI config path. This is intended for working directories
+// representing local modules nested inside another working directory.
+func (d *Dir) NewChild(subPath string) *Dir {
+	return &Dir{
+		rootDir:          filepath.Join(d.rootDir, subPath),
+		dataDir:          d.dataDir,
+		pluginCacheDir:   d.pluginCacheDir,
+		forcedPluginDirs: d.forcedPluginDirs,
+		cliConfigPath:    d.cliConfigPath,
+	}
+}
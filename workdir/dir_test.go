@@ -0,0 +1,85 @@
+package workdir
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNewDirDefaults(t *testing.T) {
+	d := NewDir("/work/root")
+
+	if got, want := d.RootDir(), "/work/root"; got != want {
+		t.Errorf("RootDir() = %q, want %q", got, want)
+	}
+	if got, want := d.DataDir(), filepath.Join("/work/root", DefaultDataDirName); got != want {
+		t.Errorf("DataDir() = %q, want %q", got, want)
+	}
+	if got := d.PluginCacheDir(); got != "" {
+		t.Errorf("PluginCacheDir() = %q, want \"\"", got)
+	}
+	if got := d.ForcedPluginDirs(); len(got) != 0 {
+		t.Errorf("ForcedPluginDirs() = %v, want empty", got)
+	}
+	if got := d.CLIConfigPath(); got != "" {
+		t.Errorf("CLIConfigPath() = %q, want \"\"", got)
+	}
+}
+
+func TestSetDataDir(t *testing.T) {
+	d := NewDir("/work/root")
+	d.SetDataDir(".terraform-alt")
+
+	if got, want := d.DataDir(), filepath.Join("/work/root", ".terraform-alt"); got != want {
+		t.Errorf("DataDir() = %q, want %q", got, want)
+	}
+}
+
+func TestSetters(t *testing.T) {
+	d := NewDir("/work/root")
+	d.SetPluginCacheDir("/cache")
+	d.SetForcedPluginDirs([]string{"/plugins/a", "/plugins/b"})
+	d.SetCLIConfigPath("/work/root/dev.tfrc")
+
+	if got, want := d.PluginCacheDir(), "/cache"; got != want {
+		t.Errorf("PluginCacheDir() = %q, want %q", got, want)
+	}
+	if got, want := d.ForcedPluginDirs(), []string{"/plugins/a", "/plugins/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ForcedPluginDirs() = %v, want %v", got, want)
+	}
+	if got, want := d.CLIConfigPath(), "/work/root/dev.tfrc"; got != want {
+		t.Errorf("CLIConfigPath() = %q, want %q", got, want)
+	}
+}
+
+func TestNewChildInheritance(t *testing.T) {
+	parent := NewDir("/work/root")
+	parent.SetDataDir(".terraform-alt")
+	parent.SetPluginCacheDir("/cache")
+	parent.SetForcedPluginDirs([]string{"/plugins/a"})
+	parent.SetCLIConfigPath("/work/root/dev.tfrc")
+
+	child := parent.NewChild("modules/child")
+
+	if got, want := child.RootDir(), filepath.Join("/work/root", "modules/child"); got != want {
+		t.Errorf("child.RootDir() = %q, want %q", got, want)
+	}
+	if got, want := child.DataDir(), filepath.Join(child.RootDir(), ".terraform-alt"); got != want {
+		t.Errorf("child.DataDir() = %q, want %q", got, want)
+	}
+	if got, want := child.PluginCacheDir(), "/cache"; got != want {
+		t.Errorf("child.PluginCacheDir() = %q, want %q", got, want)
+	}
+	if got, want := child.ForcedPluginDirs(), []string{"/plugins/a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("child.ForcedPluginDirs() = %v, want %v", got, want)
+	}
+	if got, want := child.CLIConfigPath(), "/work/root/dev.tfrc"; got != want {
+		t.Errorf("child.CLIConfigPath() = %q, want %q", got, want)
+	}
+
+	// Mutating the child must not affect the parent.
+	child.SetPluginCacheDir("/other-cache")
+	if got, want := parent.PluginCacheDir(), "/cache"; got != want {
+		t.Errorf("parent.PluginCacheDir() changed to %q after mutating child, want unchanged %q", got, want)
+	}
+}
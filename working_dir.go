@@ -0,0 +1,130 @@
+package tftest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/kmoe/terraform-plugin-test/workdir"
+)
+
+// WorkingDir represents a distinct working directory that can be used for
+// running Terraform commands.
+//
+// Each test should typically allocate its own working directory by calling
+// Helper.NewWorkingDir, use it for the duration of that test, and then call
+// Close (usually via defer) to dispose of it at the end.
+//
+// WorkingDir itself makes no decisions about where its supporting files
+// live; all of that is delegated to its workdir.Dir.
+type WorkingDir struct {
+	h *Helper
+
+	dir *workdir.Dir
+}
+
+// Close deletes the directories and files created to represent the
+// receiving working directory. After this method is called, the working
+// directory object is invalid and may no longer be used.
+func (wd *WorkingDir) Close() error {
+	return os.RemoveAll(wd.dir.RootDir())
+}
+
+// SetConfig sets a new configuration for the working directory, to be used
+// by future calls to Init and other Terraform commands.
+func (wd *WorkingDir) SetConfig(cfg string) error {
+	outFilename := filepath.Join(wd.dir.RootDir(), "main.tf")
+	return ioutil.WriteFile(outFilename, []byte(cfg), 0644)
+}
+
+// SetForcedPluginDirs sets the directories that will be passed to
+// "terraform init" as -plugin-dir arguments, overriding the default chosen
+// when the working directory was created.
+func (wd *WorkingDir) SetForcedPluginDirs(dirs []string) {
+	wd.dir.SetForcedPluginDirs(dirs)
+}
+
+// ForcedPluginDirs returns the directories that will be passed to
+// "terraform init" as -plugin-dir arguments.
+func (wd *WorkingDir) ForcedPluginDirs() []string {
+	return wd.dir.ForcedPluginDirs()
+}
+
+// DataDir returns the directory where Terraform keeps its own internal
+// state for this working directory.
+func (wd *WorkingDir) DataDir() string {
+	return wd.dir.DataDir()
+}
+
+// SetDataDir overrides the name of the working directory's data directory,
+// relative to its root, setting TF_DATA_DIR for future Terraform commands
+// run in this working directory.
+func (wd *WorkingDir) SetDataDir(name string) {
+	wd.dir.SetDataDir(name)
+}
+
+// NewChild creates a new WorkingDir rooted in a subdirectory of the
+// receiver, inheriting its forced plugin dirs, plugin cache dir and CLI
+// config. This is for tests that need to drive Terraform against a local
+// module nested inside another working directory.
+func (wd *WorkingDir) NewChild(subPath string) (*WorkingDir, error) {
+	childDir := wd.dir.NewChild(subPath)
+	if err := os.MkdirAll(childDir.RootDir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create child working directory: %s", err)
+	}
+
+	return &WorkingDir{
+		h:   wd.h,
+		dir: childDir,
+	}, nil
+}
+
+// runTerraform runs the configured Terraform CLI executable with the given
+// arguments inside the working directory, returning an error if it exits
+// non-zero.
+func (wd *WorkingDir) runTerraform(args ...string) error {
+	cmd := exec.Command(wd.h.TerraformExecPath(), args...)
+	cmd.Dir = wd.dir.RootDir()
+	cmd.Env = os.Environ()
+	if pluginCacheDir := wd.dir.PluginCacheDir(); pluginCacheDir != "" {
+		cmd.Env = append(cmd.Env, "TF_PLUGIN_CACHE_DIR="+pluginCacheDir)
+	}
+	if cliConfigPath := wd.dir.CLIConfigPath(); cliConfigPath != "" {
+		cmd.Env = append(cmd.Env, "TF_CLI_CONFIG_FILE="+cliConfigPath)
+	}
+	cmd.Env = append(cmd.Env, "TF_DATA_DIR="+wd.dir.DataDir())
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// Init runs "terraform init" for the given working directory, forcing
+// Terraform to use whichever plugin directories are configured via
+// SetForcedPluginDirs (by default, the Helper's current plugin dir, or none
+// if the Helper had generated a CLI config via WriteCLIConfig when this
+// working directory was created).
+//
+// If the owning Helper has a shared plugin cache directory available (see
+// Helper.PluginCacheDir), it is set via TF_PLUGIN_CACHE_DIR so that this
+// call, and every other WorkingDir sharing the same Helper, can reuse
+// already-installed plugins instead of paying the full init cost each time.
+func (wd *WorkingDir) Init() error {
+	args := []string{"init"}
+	for _, pluginDir := range wd.dir.ForcedPluginDirs() {
+		args = append(args, "-plugin-dir="+pluginDir)
+	}
+	if err := wd.runTerraform(args...); err != nil {
+		return fmt.Errorf("terraform init failed: %s", err)
+	}
+	return nil
+}
+
+// Apply runs "terraform apply" for the given working directory.
+func (wd *WorkingDir) Apply() error {
+	if err := wd.runTerraform("apply", "-auto-approve"); err != nil {
+		return fmt.Errorf("terraform apply failed: %s", err)
+	}
+	return nil
+}
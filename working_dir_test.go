@@ -0,0 +1,125 @@
+package tftest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kmoe/terraform-plugin-test/workdir"
+)
+
+// writeFakeTerraform writes a shell script standing in for the Terraform
+// CLI executable: when run, it records its arguments and environment into
+// files alongside whatever it was given as its working directory, so tests
+// can assert on what WorkingDir actually passed to the real thing.
+func writeFakeTerraform(t *testing.T, dir string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "fake-terraform")
+	script := "#!/bin/sh\necho \"$@\" > argsdump\nenv > envdump\n"
+	if err := ioutil.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTestWorkingDir(t *testing.T, baseDir, terraformExec string) *WorkingDir {
+	t.Helper()
+
+	rootDir, err := ioutil.TempDir(baseDir, "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := workdir.NewDir(rootDir)
+	dir.SetForcedPluginDirs([]string{"/plugins/current"})
+	dir.SetPluginCacheDir(filepath.Join(baseDir, "plugin-cache"))
+	dir.SetCLIConfigPath(filepath.Join(baseDir, "dev.tfrc"))
+
+	return &WorkingDir{
+		h:   &Helper{terraformExec: terraformExec},
+		dir: dir,
+	}
+}
+
+func readDumpFile(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("failed to read %s: %s", name, err)
+	}
+	return string(content)
+}
+
+func TestWorkingDirPropagatesEnvAndArgs(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "tftest-workingdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+
+	terraformExec := writeFakeTerraform(t, baseDir)
+	wd := newTestWorkingDir(t, baseDir, terraformExec)
+
+	if err := wd.Init(); err != nil {
+		t.Fatalf("Init returned an error: %s", err)
+	}
+
+	args := readDumpFile(t, wd.dir.RootDir(), "argsdump")
+	if !strings.Contains(args, "-plugin-dir=/plugins/current") {
+		t.Errorf("args = %q, want it to contain -plugin-dir=/plugins/current", args)
+	}
+
+	env := readDumpFile(t, wd.dir.RootDir(), "envdump")
+	if want := "TF_DATA_DIR=" + wd.DataDir(); !strings.Contains(env, want) {
+		t.Errorf("env did not contain %q:\n%s", want, env)
+	}
+	if want := "TF_PLUGIN_CACHE_DIR=" + filepath.Join(baseDir, "plugin-cache"); !strings.Contains(env, want) {
+		t.Errorf("env did not contain %q:\n%s", want, env)
+	}
+	if want := "TF_CLI_CONFIG_FILE=" + filepath.Join(baseDir, "dev.tfrc"); !strings.Contains(env, want) {
+		t.Errorf("env did not contain %q:\n%s", want, env)
+	}
+}
+
+func TestWorkingDirNewChildPropagatesToExec(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "tftest-workingdir-child")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+
+	terraformExec := writeFakeTerraform(t, baseDir)
+	parent := newTestWorkingDir(t, baseDir, terraformExec)
+
+	child, err := parent.NewChild("modules/child")
+	if err != nil {
+		t.Fatalf("NewChild returned an error: %s", err)
+	}
+
+	if err := child.Init(); err != nil {
+		t.Fatalf("child Init returned an error: %s", err)
+	}
+
+	args := readDumpFile(t, child.dir.RootDir(), "argsdump")
+	if !strings.Contains(args, "-plugin-dir=/plugins/current") {
+		t.Errorf("child args = %q, want it to contain -plugin-dir=/plugins/current", args)
+	}
+
+	env := readDumpFile(t, child.dir.RootDir(), "envdump")
+	if want := "TF_DATA_DIR=" + child.DataDir(); !strings.Contains(env, want) {
+		t.Errorf("child env did not contain %q:\n%s", want, env)
+	}
+	if child.DataDir() == parent.DataDir() {
+		t.Error("expected child's data dir to be distinct from its parent's")
+	}
+	if want := "TF_PLUGIN_CACHE_DIR=" + filepath.Join(baseDir, "plugin-cache"); !strings.Contains(env, want) {
+		t.Errorf("child env did not contain %q:\n%s", want, env)
+	}
+	if want := "TF_CLI_CONFIG_FILE=" + filepath.Join(baseDir, "dev.tfrc"); !strings.Contains(env, want) {
+		t.Errorf("child env did not contain %q:\n%s", want, env)
+	}
+}